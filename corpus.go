@@ -0,0 +1,44 @@
+package main
+
+import (
+	_ "embed"
+	"math/rand"
+	"strings"
+)
+
+//go:embed moby_dick.txt
+var book string
+
+func Corpus() string {
+	return book
+}
+
+// CorpusWords splits the corpus the same way the benchmarks do. Each call
+// returns a new slice, so callers are free to modify it.
+func CorpusWords() []string {
+	return strings.Split(book, " ")
+}
+
+const maxGeneratedWordLen = 12
+
+// GenerateWords returns n pseudo-random lowercase words of 1 to
+// maxGeneratedWordLen letters. The same seed always yields the same words. A
+// negative n is treated as zero.
+func GenerateWords(n int, seed int64) []string {
+	if n < 0 {
+		n = 0
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	words := make([]string, 0, n)
+	buf := make([]byte, maxGeneratedWordLen)
+	for i := 0; i < n; i++ {
+		word := buf[:1+rng.Intn(maxGeneratedWordLen)]
+		for j := range word {
+			word[j] = 'a' + byte(rng.Intn(26))
+		}
+		words = append(words, string(word))
+	}
+
+	return words
+}
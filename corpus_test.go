@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCorpus(t *testing.T) {
+	if !strings.Contains(Corpus(), "Call me Ishmael.") {
+		t.Error("corpus doesn't look like Moby Dick")
+	}
+
+	words := CorpusWords()
+	if len(words) != len(strings.Split(Corpus(), " ")) {
+		t.Errorf("got %d words, want the corpus split on spaces", len(words))
+	}
+	words[0] = "changed"
+	if CorpusWords()[0] == "changed" {
+		t.Error("CorpusWords shares its slice between calls")
+	}
+}
+
+func TestGenerateWords(t *testing.T) {
+	words := GenerateWords(1000, 42)
+	if len(words) != 1000 {
+		t.Fatalf("got %d words, want 1000", len(words))
+	}
+	for _, w := range words {
+		if len(w) < 1 || len(w) > maxGeneratedWordLen || strings.Trim(w, "abcdefghijklmnopqrstuvwxyz") != "" {
+			t.Fatalf("got word %q, want 1 to %d lowercase letters", w, maxGeneratedWordLen)
+		}
+	}
+
+	if golden := []string{"rukptt", "ezptneuvu", "huksqv"}; !slices.Equal(words[:3], golden) {
+		t.Errorf("got %q for seed 42, want %q; changing the generator makes old benchmark results incomparable", words[:3], golden)
+	}
+	if !slices.Equal(words, GenerateWords(1000, 42)) {
+		t.Error("same seed generated different words")
+	}
+	if slices.Equal(words, GenerateWords(1000, 43)) {
+		t.Error("different seeds generated the same words")
+	}
+	for _, n := range []int{0, -1} {
+		if got := GenerateWords(n, 42); got == nil || len(got) != 0 {
+			t.Errorf("n=%d: got %v, want an empty slice", n, got)
+		}
+	}
+}
+
+func BenchmarkGeneratedWords(b *testing.B) {
+	for _, size := range []int{10, 100, 1_000, 10_000, 100_000} {
+		words := GenerateWords(size, 1)
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ProcessWordsFaster(words)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sort"
+)
+
+func CountWords(rawWords []string) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range rawWords {
+		counts[process(w)]++
+	}
+
+	return counts
+}
+
+// TopWords returns the n most frequent processed words, with each Word's index
+// holding its count instead of a position. Ties are ordered alphabetically.
+func TopWords(rawWords []string, n int) []Word {
+	counts := CountWords(rawWords)
+	words := make([]Word, 0, len(counts))
+	for w, c := range counts {
+		words = append(words, Word{w, c})
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].index != words[j].index {
+			return words[i].index > words[j].index
+		}
+		return words[i].word < words[j].word
+	})
+
+	if n < 0 {
+		n = 0
+	}
+	if n < len(words) {
+		words = words[:n]
+	}
+
+	return words
+}
+
+// ProcessWordsUnique splits processed words into their first occurrences and
+// every later repeat, both keeping their original indices.
+func ProcessWordsUnique(rawWords []string) (unique []Word, duplicates []Word) {
+	unique = make([]Word, 0)
+	duplicates = make([]Word, 0)
+	seen := make(map[string]struct{})
+	for i, w := range rawWords {
+		word := Word{process(w), i}
+		if _, ok := seen[word.word]; ok {
+			duplicates = append(duplicates, word)
+			continue
+		}
+		seen[word.word] = struct{}{}
+		unique = append(unique, word)
+	}
+
+	return unique, duplicates
+}
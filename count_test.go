@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCountWords(t *testing.T) {
+	counts := CountWords([]string{"The", "whale", "the", "THE", "Whale", "ship"})
+	want := map[string]int{"THE": 3, "WHALE": 2, "SHIP": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("got %v, want %v", counts, want)
+	}
+	for w, c := range want {
+		if counts[w] != c {
+			t.Errorf("count of %q: got %d, want %d", w, counts[w], c)
+		}
+	}
+}
+
+func TestTopWords(t *testing.T) {
+	raw := []string{"The", "whale", "the", "THE", "Whale", "ship", "sea"}
+	assertWords(t, TopWords(raw, 3), []Word{{"THE", 3}, {"WHALE", 2}, {"SEA", 1}})
+	assertWords(t, TopWords(raw, 0), []Word{})
+	if got := TopWords(raw, 10); len(got) != 4 {
+		t.Errorf("got %d words, want 4", len(got))
+	}
+}
+
+func TestProcessWordsUnique(t *testing.T) {
+	unique, duplicates := ProcessWordsUnique([]string{"The", "whale", "the", "ship", "THE", "Whale"})
+	assertWords(t, unique, []Word{{"THE", 0}, {"WHALE", 1}, {"SHIP", 3}})
+	assertWords(t, duplicates, []Word{{"THE", 2}, {"THE", 4}, {"WHALE", 5}})
+}
+
+func BenchmarkCountWords(b *testing.B) {
+	words := CorpusWords()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CountWords(words)
+	}
+}
+
+func BenchmarkProcessWordsUnique(b *testing.B) {
+	words := CorpusWords()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessWordsUnique(words)
+	}
+}
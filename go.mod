@@ -1,3 +1,5 @@
 module github.com/dubonzi/slice_performance
 
-go 1.16
+go 1.21
+
+require golang.org/x/text v0.21.0
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// IndexMap keys words by their original index. If several words share an
+// index, the last one in the slice wins.
+func IndexMap(words []Word) map[int]Word {
+	m := make(map[int]Word, len(words))
+	for _, w := range words {
+		m[w.index] = w
+	}
+
+	return m
+}
+
+// WordAt scans words for the given original index, matching IndexMap's
+// last-write-wins behavior. Use IndexMap instead for repeated lookups.
+func WordAt(words []Word, idx int) (Word, bool) {
+	for i := len(words) - 1; i >= 0; i-- {
+		if words[i].index == idx {
+			return words[i], true
+		}
+	}
+
+	return Word{}, false
+}
+
+// WordIndex answers prefix queries over processed words using a sorted copy
+// of them and binary search.
+type WordIndex struct {
+	words []Word
+}
+
+func BuildIndex(words []Word) *WordIndex {
+	sorted := slices.Clone(words)
+	slices.SortFunc(sorted, CompareWords)
+
+	return &WordIndex{sorted}
+}
+
+// WithPrefix returns the words starting with prefix, ordered by text and then
+// index. The prefix is processed like the words, so queries are
+// case-insensitive.
+func (i *WordIndex) WithPrefix(prefix string) []Word {
+	prefix = process(prefix)
+	start, _ := slices.BinarySearchFunc(i.words, prefix, func(w Word, prefix string) int {
+		return strings.Compare(w.word, prefix)
+	})
+	end := start
+	for end < len(i.words) && strings.HasPrefix(i.words[end].word, prefix) {
+		end++
+	}
+
+	return slices.Clone(i.words[start:end])
+}
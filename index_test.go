@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestIndexMap(t *testing.T) {
+	words := ProcessWordsNonEmpty([]string{"call", "", "me", " ", "ishmael"})
+	m := IndexMap(words)
+	want := map[int]Word{0: {"CALL", 0}, 2: {"ME", 2}, 4: {"ISHMAEL", 4}}
+	if len(m) != len(want) {
+		t.Fatalf("got %v, want %v", m, want)
+	}
+	for i, w := range want {
+		if m[i] != w {
+			t.Errorf("index %d: got %v, want %v", i, m[i], w)
+		}
+	}
+
+	dup := IndexMap([]Word{{"FIRST", 1}, {"LAST", 1}})
+	if dup[1] != (Word{"LAST", 1}) {
+		t.Errorf("got %v for duplicate index, want last write to win", dup[1])
+	}
+}
+
+func TestWordAt(t *testing.T) {
+	words := ProcessWordsNonEmpty([]string{"call", "", "me", " ", "ishmael"})
+	if w, ok := WordAt(words, 4); !ok || w != (Word{"ISHMAEL", 4}) {
+		t.Errorf("got %v, %v, want ISHMAEL@4", w, ok)
+	}
+	if w, ok := WordAt(words, 1); ok {
+		t.Errorf("got %v for skipped index, want not found", w)
+	}
+	if w, _ := WordAt([]Word{{"FIRST", 1}, {"LAST", 1}}, 1); w != (Word{"LAST", 1}) {
+		t.Errorf("got %v for duplicate index, want last match", w)
+	}
+}
+
+func TestWordIndex(t *testing.T) {
+	words := ProcessWords([]string{"whale", "Ahab", "whaling", "ship", "what", "Whale", "wha"})
+	index := BuildIndex(words)
+
+	assertWords(t, index.WithPrefix("Whal"), []Word{{"WHALE", 0}, {"WHALE", 5}, {"WHALING", 2}})
+	assertWords(t, index.WithPrefix("wha"), []Word{{"WHA", 6}, {"WHALE", 0}, {"WHALE", 5}, {"WHALING", 2}, {"WHAT", 4}})
+	assertWords(t, index.WithPrefix("ahab"), []Word{{"AHAB", 1}})
+	assertWords(t, index.WithPrefix("zzz"), []Word{})
+	if got := index.WithPrefix(""); len(got) != len(words) {
+		t.Errorf("got %d words for an empty prefix, want all %d", len(got), len(words))
+	}
+
+	index.WithPrefix("ship")[0] = Word{"CHANGED", 0}
+	assertWords(t, index.WithPrefix("ship"), []Word{{"SHIP", 3}})
+	words[0] = Word{"CHANGED", 0}
+	assertWords(t, index.WithPrefix("chan"), []Word{})
+}
+
+func BenchmarkWordIndex(b *testing.B) {
+	index := BuildIndex(ProcessWordsFaster(CorpusWords()))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.WithPrefix("whal")
+	}
+}
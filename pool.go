@@ -0,0 +1,36 @@
+package main
+
+import (
+	"sync"
+)
+
+var wordSlicePool = sync.Pool{
+	New: func() any { return new([]Word) },
+}
+
+// GetWordSlice returns a pointer to an empty slice from the pool, reusing the
+// capacity of a slice previously handed to PutWordSlice when one is
+// available. The pointer itself is what gets recycled, so pass the same one
+// back to PutWordSlice; putting a fresh pointer would cost an allocation.
+func GetWordSlice() *[]Word {
+	words := wordSlicePool.Get().(*[]Word)
+	*words = (*words)[:0]
+	return words
+}
+
+// PutWordSlice returns words to the pool. The caller must not use *words, or
+// any slice sharing its backing array, after calling PutWordSlice.
+func PutWordSlice(words *[]Word) {
+	all := (*words)[:cap(*words)]
+	clear(all) // drop the string references so they can be collected
+	*words = all[:0]
+	wordSlicePool.Put(words)
+}
+
+// ProcessWordsPooled processes into a slice taken from the pool. Hand the
+// result back with PutWordSlice once it is no longer needed.
+func ProcessWordsPooled(rawWords []string) *[]Word {
+	words := GetWordSlice()
+	*words = ProcessWordsInto(*words, rawWords)
+	return words
+}
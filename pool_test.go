@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestProcessWordsPooled(t *testing.T) {
+	raw := []string{"call", "me", "ishmael"}
+	words := ProcessWordsPooled(raw)
+	assertWords(t, *words, ProcessWordsFaster(raw))
+	PutWordSlice(words)
+
+	words = ProcessWordsPooled(raw[:1])
+	assertWords(t, *words, []Word{{"CALL", 0}})
+	PutWordSlice(words)
+
+	if got := GetWordSlice(); len(*got) != 0 {
+		t.Errorf("got %d words from the pool, want 0", len(*got))
+	}
+
+	upper := []string{"CALL", "ME", "ISHMAEL"} // strings.ToUpper doesn't allocate for upper case input
+	PutWordSlice(ProcessWordsPooled(upper))
+	if allocs := testing.AllocsPerRun(100, func() { PutWordSlice(ProcessWordsPooled(upper)) }); allocs != 0 {
+		t.Errorf("got %v allocations for a pooled round trip, want 0", allocs)
+	}
+}
+
+func BenchmarkProcessWordsPooled(b *testing.B) { // Pooled saves the result slice: one allocation and its bytes
+	words := CorpusWords()
+	b.Run("Faster", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsFaster(words)
+		}
+	})
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			PutWordSlice(ProcessWordsPooled(words))
+		}
+	})
+}
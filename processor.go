@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+)
+
+// WordProcessor bundles processing options behind a single entry point. The
+// zero value behaves like ProcessWordsFaster.
+type WordProcessor struct {
+	// Transform is applied to each word. Nil means strings.ToUpper.
+	Transform func(string) string
+	// SkipEmpty drops words that are empty after trimming whitespace,
+	// like ProcessWordsNonEmpty.
+	SkipEmpty bool
+	// Parallelism is the number of goroutines used. Zero or one processes
+	// sequentially, a negative value uses runtime.NumCPU().
+	Parallelism int
+}
+
+func (p WordProcessor) Process(rawWords []string) []Word {
+	transform := p.Transform
+	if transform == nil {
+		transform = process
+	}
+
+	var words []Word
+	if p.Parallelism < 0 || p.Parallelism > 1 {
+		words = processWordsParallel(rawWords, p.Parallelism, transform)
+	} else {
+		words = processWords(rawWords, len(rawWords), 0, transform)
+	}
+
+	if p.SkipEmpty {
+		kept := words[:0]
+		for _, w := range words {
+			if strings.TrimSpace(rawWords[w.index]) != "" {
+				kept = append(kept, w)
+			}
+		}
+		words = kept
+	}
+
+	return words
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordProcessor(t *testing.T) {
+	raw := append(CorpusWords()[:500], "", " ", "\t")
+	tests := []struct {
+		name string
+		p    WordProcessor
+		want []Word
+	}{
+		{"zero", WordProcessor{}, ProcessWordsFaster(raw)},
+		{"skip empty", WordProcessor{SkipEmpty: true}, ProcessWordsNonEmpty(raw)},
+		{"transform", WordProcessor{Transform: strings.ToLower}, ProcessWordsWith(raw, strings.ToLower)},
+		{"parallel", WordProcessor{Parallelism: 4}, ProcessWordsFaster(raw)},
+		{"parallel skip empty", WordProcessor{Parallelism: -1, SkipEmpty: true}, ProcessWordsNonEmpty(raw)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertWords(t, tt.p.Process(raw), tt.want)
+		})
+	}
+}
+
+func BenchmarkWordProcessor(b *testing.B) {
+	benchmarkSizes(b, WordProcessor{}.Process)
+}
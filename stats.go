@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type Stats struct {
+	TotalWords int
+	// EmptyWords counts words that are empty after trimming whitespace.
+	EmptyWords int
+	// LongestWord is the processed word with the most runes; the first one
+	// wins ties.
+	LongestWord string
+	// TotalRunes is the number of runes across all processed words.
+	TotalRunes int
+}
+
+// ProcessWithStats processes words like ProcessWordsFaster while gathering
+// Stats in the same pass.
+func ProcessWithStats(rawWords []string) ([]Word, Stats) {
+	var stats Stats
+	longest := 0
+	words := make([]Word, 0, len(rawWords))
+	for i, w := range rawWords {
+		p := process(w)
+		words = append(words, Word{p, i})
+
+		if strings.TrimSpace(w) == "" {
+			stats.EmptyWords++
+		}
+		runes := utf8.RuneCountInString(p)
+		stats.TotalRunes += runes
+		if runes > longest {
+			longest = runes
+			stats.LongestWord = p
+		}
+	}
+	stats.TotalWords = len(words)
+
+	return words, stats
+}
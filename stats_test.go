@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestProcessWithStats(t *testing.T) {
+	raw := []string{"call", "", "me", " ", "größe", "ishmae"}
+	words, stats := ProcessWithStats(raw)
+	assertWords(t, words, ProcessWordsFaster(raw))
+
+	want := Stats{TotalWords: 6, EmptyWords: 2, LongestWord: "ISHMAE", TotalRunes: 18}
+	if stats != want {
+		t.Errorf("got %+v, want %+v", stats, want)
+	}
+
+	if _, stats := ProcessWithStats(nil); stats != (Stats{}) {
+		t.Errorf("got %+v for nil input, want zero Stats", stats)
+	}
+}
+
+func BenchmarkProcessWithStats(b *testing.B) {
+	words := CorpusWords()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessWithStats(words)
+	}
+}
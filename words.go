@@ -1,7 +1,31 @@
+// Package main compares ways of building slices of processed words, with
+// benchmarks measuring what each costs.
+//
+// Every ProcessWords* function returns a non-nil, zero-length slice for nil
+// or empty input, so len checks and JSON encoding behave the same for every
+// empty result. ProcessWordsBatched returns [][]Word and follows its own
+// rule: ProcessWordsBatched(nil, 0) returns one empty batch, while
+// ProcessWordsBatched(nil, 2) returns no batches at all.
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
 )
 
 type Word struct {
@@ -9,9 +33,89 @@ type Word struct {
 	index int
 }
 
+// WordPair keeps the source token next to its processed form. On 64-bit
+// platforms it takes 40 bytes versus Word's 24; Original shares the input
+// string's memory, so the extra cost is just the 16-byte string header.
+type WordPair struct {
+	Original  string
+	Processed string
+	Index     int
+}
+
+func (w Word) Word() string {
+	return w.word
+}
+
+func (w Word) Index() int {
+	return w.index
+}
+
+func (w Word) Equal(other Word) bool {
+	return w.word == other.word && w.index == other.index
+}
+
+// CompareWords orders words by processed text, then by index. It can be
+// passed directly to slices.SortFunc.
+func CompareWords(a, b Word) int {
+	if c := strings.Compare(a.word, b.word); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.index, b.index)
+}
+
+// String formats w as WORD@INDEX using a single allocation.
+func (w Word) String() string {
+	var buf [20]byte
+	return w.word + "@" + string(strconv.AppendInt(buf[:0], int64(w.index), 10))
+}
+
+func FormatWords(words []Word) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, w := range words {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(w.String())
+	}
+	sb.WriteByte(']')
+
+	return sb.String()
+}
+
+type wordJSON struct {
+	Word  string `json:"word"`
+	Index int    `json:"index"`
+}
+
+func (w Word) MarshalJSON() ([]byte, error) {
+	return json.Marshal(wordJSON{w.word, w.index})
+}
+
+func (w *Word) UnmarshalJSON(data []byte) error {
+	var v wordJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*w = Word{v.Word, v.Index}
+	return nil
+}
+
 func ProcessWords(rawWords []string) []Word {
+	return processWords(rawWords, 0, 0, process)
+}
+
+// ProcessWordsDoubling grows its result by hand, doubling the capacity every
+// time it fills up, to make the reallocations ProcessWords leaves to append
+// explicit.
+func ProcessWordsDoubling(rawWords []string) []Word {
 	words := make([]Word, 0)
 	for i, w := range rawWords {
+		if len(words) == cap(words) {
+			grown := make([]Word, len(words), 2*cap(words)+1)
+			copy(grown, words)
+			words = grown
+		}
 		words = append(words, Word{process(w), i})
 	}
 
@@ -19,11 +123,525 @@ func ProcessWords(rawWords []string) []Word {
 }
 
 func ProcessWordsFaster(rawWords []string) []Word {
+	i := -1
+	return ProcessSlice(rawWords, func(w string) Word {
+		i++
+		return Word{process(w), i}
+	})
+}
+
+func ProcessWordsIndexed(rawWords []string) []Word {
+	words := make([]Word, len(rawWords))
+	for i, w := range rawWords {
+		words[i] = Word{process(w), i}
+	}
+
+	return words
+}
+
+// ProcessWordsFrom numbers words starting at startIndex, so chunks of a larger
+// document can be processed separately while keeping indices unique.
+func ProcessWordsFrom(rawWords []string, startIndex int) []Word {
+	return processWords(rawWords, len(rawWords), startIndex, process)
+}
+
+// ProcessWordsTruncate cuts processed words down to at most maxRunes runes.
+// A maxRunes of zero or less disables truncation.
+func ProcessWordsTruncate(rawWords []string, maxRunes int) []Word {
+	if maxRunes <= 0 {
+		return ProcessWordsFaster(rawWords)
+	}
+
+	return ProcessWordsWith(rawWords, func(w string) string {
+		return truncateRunes(process(w), maxRunes)
+	})
+}
+
+func truncateRunes(s string, n int) string {
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+
+	return s
+}
+
+// ProcessWordsBytes splits raw on single spaces, like strings.Split(s, " "),
+// and processes each token. The one exception is empty input, which yields no
+// words where strings.Split would yield a single empty one. ASCII is
+// upper-cased straight into a strings.Builder, the only copy of the buffer
+// made, and words point into its string, so only non-ASCII tokens allocate
+// individually. Keeping any of the words keeps the whole buffer alive.
+func ProcessWordsBytes(raw []byte) []Word {
+	if len(raw) == 0 {
+		return make([]Word, 0)
+	}
+
+	var upper strings.Builder
+	upper.Grow(len(raw))
+	for _, c := range raw {
+		if 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper.WriteByte(c)
+	}
+	text := upper.String()
+
+	words := make([]Word, 0, bytes.Count(raw, []byte{' '})+1)
+	for i := 0; ; i++ {
+		end := strings.IndexByte(text, ' ')
+		if end < 0 {
+			end = len(text)
+		}
+		token := text[:end]
+		if !isASCII(token) {
+			token = strings.ToUpper(token)
+		}
+		words = append(words, Word{token, i})
+		if end == len(text) {
+			break
+		}
+		text = text[end+1:]
+	}
+
+	return words
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// ProcessNGrams joins each run of n consecutive processed words with spaces,
+// indexing each n-gram by the position of its first word. It returns an empty
+// slice when n <= 0 or there are fewer than n words.
+func ProcessNGrams(rawWords []string, n int) []Word {
+	if n <= 0 || n > len(rawWords) {
+		return make([]Word, 0)
+	}
+	if n == 1 {
+		return ProcessWordsFaster(rawWords)
+	}
+
+	processed := ProcessSlice(rawWords, process)
+	ngrams := make([]Word, 0, len(processed)-n+1)
+	for i := 0; i+n <= len(processed); i++ {
+		ngrams = append(ngrams, Word{strings.Join(processed[i:i+n], " "), i})
+	}
+
+	return ngrams
+}
+
+// ProcessWordsRange processes rawWords[start:end], keeping each word's index
+// relative to rawWords. Out of range bounds are clamped to the input, and an
+// empty range yields no words.
+func ProcessWordsRange(rawWords []string, start, end int) []Word {
+	start = clamp(start, len(rawWords))
+	end = clamp(end, len(rawWords))
+	if start > end {
+		start = end
+	}
+
+	return ProcessWordsFrom(rawWords[start:end], start)
+}
+
+func ProcessText(raw string) []Word {
+	return ProcessWordsFaster(strings.Fields(raw))
+}
+
+// ProcessWordsDelim splits raw on delim before processing. An empty delim
+// splits on whitespace like ProcessText. An empty raw yields no words, unlike
+// strings.Split, which would yield a single empty field; a raw consisting of
+// just delim still yields two empty words.
+func ProcessWordsDelim(raw string, delim string) []Word {
+	if raw == "" {
+		return make([]Word, 0)
+	}
+	if delim == "" {
+		return ProcessText(raw)
+	}
+
+	return ProcessWordsFaster(strings.Split(raw, delim))
+}
+
+func ProcessWordsWith(rawWords []string, transform func(string) string) []Word {
+	return processWords(rawWords, len(rawWords), 0, transform)
+}
+
+func ProcessWordsPairs(rawWords []string) []WordPair {
+	pairs := make([]WordPair, 0, len(rawWords))
+	for i, w := range rawWords {
+		pairs = append(pairs, WordPair{w, process(w), i})
+	}
+
+	return pairs
+}
+
+func ProcessWordsInto(dst []Word, rawWords []string) []Word {
+	if dst == nil {
+		dst = make([]Word, 0, len(rawWords))
+	}
+	dst = dst[:0]
+	for i, w := range rawWords {
+		dst = append(dst, Word{process(w), i})
+	}
+
+	return dst
+}
+
+// ProcessWordsArena fills the front of arena with the processed words and
+// returns them along with the unused remainder, so consecutive calls can
+// share one allocation. The result is capped at its length, so appending to
+// it never writes into the remainder. If arena is too small, a new slice is
+// allocated and arena is returned untouched.
+func ProcessWordsArena(rawWords []string, arena []Word) ([]Word, []Word) {
+	n := len(rawWords)
+	if cap(arena) < n {
+		return ProcessWordsFaster(rawWords), arena
+	}
+
+	words := ProcessWordsInto(arena[:0:n], rawWords)
+	return words, arena[n:cap(arena)]
+}
+
+// ProcessWordsMap keys words by index. Even presized, the map costs far more
+// time and memory than ProcessWordsFaster's slice for dense integer keys.
+func ProcessWordsMap(rawWords []string) map[int]Word {
+	words := make(map[int]Word, len(rawWords))
+	for i, w := range rawWords {
+		words[i] = Word{process(w), i}
+	}
+
+	return words
+}
+
+func ProcessWordsNonEmpty(rawWords []string) []Word {
+	words := make([]Word, 0, len(rawWords))
+	for i, w := range rawWords {
+		if strings.TrimSpace(w) == "" {
+			continue
+		}
+		words = append(words, Word{process(w), i}) // index stays the position in rawWords
+	}
+
+	return words
+}
+
+// ProcessWordsSorted orders words by their processed text. Ties are broken by
+// index, so repeated words keep their original relative order.
+func ProcessWordsSorted(rawWords []string) []Word {
+	words := ProcessWordsFaster(rawWords)
+	slices.SortFunc(words, CompareWords)
+
+	return words
+}
+
+// ProcessWordsBatched splits the processed words into batches of at most
+// batchSize. Batches share one backing array but are capped, so appending to
+// one batch never overwrites the next.
+func ProcessWordsBatched(rawWords []string, batchSize int) [][]Word {
+	words := ProcessWordsFaster(rawWords)
+	if batchSize <= 0 {
+		return [][]Word{words}
+	}
+
+	if batchSize > len(words) { // keeps the count and start+batchSize below from overflowing
+		batchSize = max(len(words), 1)
+	}
+
+	batches := make([][]Word, 0, (len(words)+batchSize-1)/batchSize)
+	for start := 0; start < len(words); start += batchSize {
+		end := start + batchSize
+		if end > len(words) {
+			end = len(words)
+		}
+		batches = append(batches, words[start:end:end])
+	}
+
+	return batches
+}
+
+// ProcessWordsInterned makes words with the same processed text share one
+// string, so repetitive input retains far less memory than ProcessWordsFaster.
+func ProcessWordsInterned(rawWords []string) []Word {
+	interned := make(map[string]string)
 	words := make([]Word, 0, len(rawWords))
 	for i, w := range rawWords {
+		p := process(w)
+		if s, ok := interned[p]; ok {
+			p = s
+		} else {
+			interned[p] = p
+		}
+		words = append(words, Word{p, i})
+	}
+
+	return words
+}
+
+// ProcessWordsUntil processes words in order until stop returns true, and
+// returns the words processed so far including the one that stopped it. The
+// result isn't preallocated, since the whole point is to not need most of it.
+func ProcessWordsUntil(rawWords []string, stop func(Word) bool) []Word {
+	words := make([]Word, 0)
+	for i, w := range rawWords {
+		word := Word{process(w), i}
+		words = append(words, word)
+		if stop(word) {
+			break
+		}
+	}
+
+	return words
+}
+
+// ProcessWordsMemo calls transform once per distinct raw word and reuses the
+// result for repeats, which pays off when transform is expensive. The cache
+// lives only for the duration of the call, so concurrent calls share nothing
+// and need no locking.
+func ProcessWordsMemo(rawWords []string, transform func(string) string) []Word {
+	cache := make(map[string]string)
+	return processWords(rawWords, len(rawWords), 0, func(w string) string {
+		p, ok := cache[w]
+		if !ok {
+			p = transform(w)
+			cache[w] = p
+		}
+		return p
+	})
+}
+
+func ProcessWordsFunc(rawWords []string, fn func(Word)) {
+	for i, w := range rawWords {
+		fn(Word{process(w), i})
+	}
+}
+
+// ProcessWordsValidated stops at the first transform error, returning the words
+// processed before it and the error annotated with the failing index.
+func ProcessWordsValidated(rawWords []string, transform func(string) (string, error)) ([]Word, error) {
+	words := make([]Word, 0, len(rawWords))
+	for i, w := range rawWords {
+		processed, err := transform(w)
+		if err != nil {
+			return words, fmt.Errorf("word %d: %w", i, err)
+		}
+		words = append(words, Word{processed, i})
+	}
+
+	return words, nil
+}
+
+const ctxCheckInterval = 1024
+
+// ProcessWordsContext checks ctx every ctxCheckInterval words and, once it is
+// done, returns the words processed so far along with ctx.Err().
+func ProcessWordsContext(ctx context.Context, rawWords []string) ([]Word, error) {
+	words := make([]Word, 0, len(rawWords))
+	for i, w := range rawWords {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return words, err
+			}
+		}
 		words = append(words, Word{process(w), i})
 	}
 
+	return words, nil
+}
+
+const wordChanBuffer = 64
+
+// ProcessWordsChan processes words in a new goroutine and sends them, in
+// order, on the returned channel. The channel is closed once every word is
+// sent or ctx is done; after ctx is done at most one more word is sent beyond
+// those already buffered. Callers must either drain the channel or cancel
+// ctx, otherwise the goroutine blocks on a send forever.
+func ProcessWordsChan(ctx context.Context, rawWords []string) <-chan Word {
+	ch := make(chan Word, wordChanBuffer)
+	go func() {
+		defer close(ch)
+		for i, w := range rawWords {
+			if ctx.Err() != nil { // select picks randomly when both cases are ready
+				return
+			}
+			select {
+			case ch <- Word{process(w), i}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+func ProcessWordsParallel(rawWords []string, workers int) []Word {
+	return processWordsParallel(rawWords, workers, process)
+}
+
+func processWordsParallel(rawWords []string, workers int, transform func(string) string) []Word {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(rawWords) {
+		workers = len(rawWords)
+	}
+
+	words := make([]Word, len(rawWords))
+	if workers == 0 {
+		return words
+	}
+
+	chunk := (len(rawWords) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(rawWords); start += chunk {
+		end := start + chunk
+		if end > len(rawWords) {
+			end = len(rawWords)
+		}
+		wg.Add(1)
+		go func(start, end int) { // each worker owns words[start:end], so no locking is needed
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				words[i] = Word{transform(rawWords[i]), i}
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	return words
+}
+
+func ProcessWordsReader(r io.Reader) ([]Word, error) {
+	words := make([]Word, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for i := 0; scanner.Scan(); i++ {
+		words = append(words, Word{process(scanner.Text()), i})
+	}
+
+	return words, scanner.Err()
+}
+
+// ProcessWordsFold upper-cases words using the casing rules of tag, so for
+// example German "ß" becomes "SS" and Turkish "i" becomes "İ". With
+// language.Und it falls back to strings.ToUpper, matching ProcessWordsFaster.
+func ProcessWordsFold(rawWords []string, tag language.Tag) []Word {
+	if tag == language.Und {
+		return ProcessWordsWith(rawWords, process)
+	}
+
+	caser := cases.Upper(tag)
+	return ProcessWordsWith(rawWords, caser.String)
+}
+
+// ProcessWordsTitle title-cases words for display, turning both "hello" and
+// "HELLO" into "Hello".
+func ProcessWordsTitle(rawWords []string) []Word {
+	caser := cases.Title(language.Und)
+	return ProcessWordsWith(rawWords, caser.String)
+}
+
+// ProcessWordsPrefixed returns prefix followed by the processed word. Rather
+// than concatenating per word, every prefix+process(w) is written into one
+// strings.Builder and each word is a substring of its final string. Beyond
+// process's own allocation per word, the whole call then costs a constant
+// number of allocations, where concatenating costs one more per word. Keeping
+// any of the words keeps the whole buffer alive.
+func ProcessWordsPrefixed(rawWords []string, prefix string) []Word {
+	size := 0
+	for _, w := range rawWords {
+		size += len(prefix) + len(w) // process may change the length; Grow is only a hint
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+	ends := make([]int, len(rawWords))
+	for i, w := range rawWords {
+		sb.WriteString(prefix)
+		sb.WriteString(process(w))
+		ends[i] = sb.Len()
+	}
+
+	text := sb.String()
+	words := make([]Word, 0, len(rawWords))
+	start := 0
+	for i, end := range ends {
+		words = append(words, Word{text[start:end], i})
+		start = end
+	}
+
+	return words
+}
+
+func ProcessWordsStripPunct(rawWords []string) []Word {
+	return ProcessWordsWith(rawWords, func(w string) string {
+		return process(strings.TrimFunc(w, unicode.IsPunct))
+	})
+}
+
+// CloneWords returns a copy of words that can be modified without affecting
+// the original. Results of the ProcessWords* functions, such as
+// ProcessWordsFaster or ProcessWordsRange, are fresh slices that are already
+// safe to mutate. Slices from FirstN, ProcessWordsInto (which reuses dst),
+// ProcessWordsBatched, ProcessWordsArena and ProcessWordsPooled share a
+// backing array with other slices, so clone them before modifying them if
+// that sharing matters.
+func CloneWords(words []Word) []Word {
+	return slices.Clone(words)
+}
+
+// FirstN reslices words to its first n elements. The result keeps the whole
+// backing array alive for as long as it is referenced; use FirstNCopy when
+// only a small prefix of a large slice needs to be kept around.
+func FirstN(words []Word, n int) []Word {
+	return words[:clamp(n, len(words))]
+}
+
+// FirstNCopy copies the first n words into a new slice of exactly that size,
+// letting the original backing array be garbage collected.
+func FirstNCopy(words []Word, n int) []Word {
+	first := make([]Word, clamp(n, len(words)))
+	copy(first, words)
+
+	return first
+}
+
+func clamp(n, limit int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > limit {
+		return limit
+	}
+	return n
+}
+
+// ProcessSlice maps in to a new slice, calling fn on each element in order.
+// The result is allocated once with the capacity it needs.
+func ProcessSlice[T, U any](in []T, fn func(T) U) []U {
+	out := make([]U, 0, len(in))
+	for _, v := range in {
+		out = append(out, fn(v))
+	}
+
+	return out
+}
+
+func processWords(rawWords []string, capacity, startIndex int, transform func(string) string) []Word {
+	words := make([]Word, 0, capacity)
+	for i, w := range rawWords {
+		words = append(words, Word{transform(w), startIndex + i})
+	}
+
 	return words
 }
 
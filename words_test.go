@@ -1,22 +1,1127 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+
+	"golang.org/x/text/language"
 )
 
+func benchmarkSizes(b *testing.B, fn func([]string) []Word) {
+	words := CorpusWords()
+	for _, size := range []int{10, 100, 1_000, 10_000, len(words)} {
+		input := words[:size]
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fn(input)
+			}
+		})
+	}
+}
+
 func BenchmarkProcessWords(b *testing.B) {
-	words := strings.Split(book, " ")
+	benchmarkSizes(b, ProcessWords)
+}
+
+func BenchmarkProcessWordsFaster(b *testing.B) {
+	benchmarkSizes(b, ProcessWordsFaster)
+}
+
+// For the ~197k words of the full book, ProcessWords lets append reallocate 31
+// times (the runtime slows growth from 2x towards 1.25x past 256 elements),
+// ProcessWordsDoubling reallocates 18 times on its way to a capacity of
+// 262143, and ProcessWordsFaster allocates exactly once.
+func BenchmarkGrowthStrategies(b *testing.B) {
+	words := CorpusWords()
+	for _, bb := range []struct {
+		name string
+		fn   func([]string) []Word
+	}{
+		{"Append", ProcessWords},
+		{"Doubling", ProcessWordsDoubling},
+		{"Exact", ProcessWordsFaster},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bb.fn(words)
+			}
+		})
+	}
+}
+
+// BenchmarkProcessOnly and BenchmarkAllocOnly split ProcessWordsFaster's cost
+// into the transform itself and building the result slice.
+func BenchmarkProcessOnly(b *testing.B) {
+	words := CorpusWords()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		ProcessWords(words)
+		for _, w := range words {
+			process(w)
+		}
 	}
 }
 
-func BenchmarkProcessWordsFaster(b *testing.B) {
-	words := strings.Split(book, " ")
+func BenchmarkAllocOnly(b *testing.B) {
+	words := CorpusWords()
+	identity := func(w string) string { return w }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessWordsWith(words, identity)
+	}
+}
+
+func BenchmarkSplitThenProcess(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessWordsFaster(strings.Split(book, " "))
+	}
+}
+
+func BenchmarkProcessText(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		ProcessWordsFaster(words)
+		ProcessText(book)
+	}
+}
+
+func BenchmarkProcessWordsBytes(b *testing.B) {
+	raw := []byte(book)
+	b.Run("Strings", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsFaster(strings.Split(string(raw), " "))
+		}
+	})
+	b.Run("Bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsBytes(raw)
+		}
+	})
+}
+
+func BenchmarkProcessWordsPrefixed(b *testing.B) {
+	words := CorpusWords()
+	b.Run("Concat", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsWith(words, func(w string) string { return "word:" + process(w) })
+		}
+	})
+	b.Run("Builder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsPrefixed(words, "word:")
+		}
+	})
+}
+
+func BenchmarkProcessWordsPairs(b *testing.B) {
+	words := CorpusWords()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessWordsPairs(words)
+	}
+}
+
+func BenchmarkProcessWordsIndexed(b *testing.B) {
+	benchmarkSizes(b, ProcessWordsIndexed)
+}
+
+func BenchmarkProcessWordsInto(b *testing.B) { // remaining allocations come from process, not from the buffer
+	words := CorpusWords()
+	buf := make([]Word, 0, len(words))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = ProcessWordsInto(buf, words)
+	}
+}
+
+func BenchmarkProcessWordsContext(b *testing.B) {
+	benchmarkSizes(b, func(words []string) []Word {
+		result, _ := ProcessWordsContext(context.Background(), words)
+		return result
+	})
+}
+
+func BenchmarkProcessWordsSorted(b *testing.B) {
+	words := CorpusWords()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessWordsSorted(words)
+	}
+}
+
+// retainedStringBytes counts the string bytes kept alive by words, counting
+// shared strings once.
+func retainedStringBytes(words []Word) int {
+	seen := make(map[*byte]struct{})
+	total := 0
+	for _, w := range words {
+		p := unsafe.StringData(w.word)
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		total += len(w.word)
+	}
+
+	return total
+}
+
+func BenchmarkProcessWordsInterned(b *testing.B) {
+	words := CorpusWords()
+	for _, bb := range []struct {
+		name string
+		fn   func([]string) []Word
+	}{
+		{"Faster", ProcessWordsFaster},
+		{"Interned", ProcessWordsInterned},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			var result []Word
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				result = bb.fn(words)
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(retainedStringBytes(result)), "retained-string-B")
+		})
+	}
+}
+
+func BenchmarkFirstN(b *testing.B) {
+	for _, bb := range []struct {
+		name string
+		fn   func([]Word, int) []Word
+	}{
+		{"Reslice", FirstN},
+		{"Copy", FirstNCopy},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			words := ProcessWordsFaster(CorpusWords())
+			var first []Word
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				first = bb.fn(words, 10)
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(cap(first))*float64(unsafe.Sizeof(Word{})), "retained-B")
+		})
+	}
+}
+
+func BenchmarkProcessWordsArena(b *testing.B) {
+	words := CorpusWords()
+	var chunks [][]string
+	for start := 0; start < len(words); start += 100 {
+		chunks = append(chunks, words[start:clamp(start+100, len(words))])
+	}
+
+	b.Run("Faster", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, chunk := range chunks {
+				ProcessWordsFaster(chunk)
+			}
+		}
+	})
+	b.Run("Arena", func(b *testing.B) { // remaining allocations come from process
+		arena := make([]Word, len(words))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rest := arena
+			for _, chunk := range chunks {
+				_, rest = ProcessWordsArena(chunk, rest)
+			}
+		}
+	})
+}
+
+func BenchmarkProcessWordsMap(b *testing.B) {
+	words := CorpusWords()
+	b.Run("Slice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsFaster(words)
+		}
+	})
+	b.Run("Map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsMap(words)
+		}
+	})
+}
+
+func BenchmarkProcessWordsUntil(b *testing.B) {
+	words := CorpusWords()
+	target := len(words) / 10
+	b.Run("Full", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsFaster(words)
+		}
+	})
+	b.Run("Until10Percent", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsUntil(words, func(w Word) bool { return w.index == target })
+		}
+	})
+}
+
+// slowProcess behaves like process but burns CPU hashing the word first, to
+// stand in for an expensive transform.
+func slowProcess(w string) string {
+	sum := sha256.Sum256([]byte(w))
+	for i := 0; i < 20; i++ {
+		sum = sha256.Sum256(sum[:])
+	}
+	return process(w)
+}
+
+func BenchmarkProcessWordsMemo(b *testing.B) {
+	words := CorpusWords()
+	b.Run("Plain", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsWith(words, slowProcess)
+		}
+	})
+	b.Run("Memo", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ProcessWordsMemo(words, slowProcess)
+		}
+	})
+}
+
+func BenchmarkProcessWordsFunc(b *testing.B) { // remaining allocations come from process, not from a result slice
+	words := CorpusWords()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessWordsFunc(words, func(Word) {})
+	}
+}
+
+func BenchmarkProcessWordsChan(b *testing.B) {
+	words := CorpusWords()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for range ProcessWordsChan(context.Background(), words) {
+		}
+	}
+}
+
+func BenchmarkProcessWordsParallel(b *testing.B) {
+	benchmarkSizes(b, func(words []string) []Word {
+		return ProcessWordsParallel(words, 0)
+	})
+}
+
+func BenchmarkProcessWordsReader(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ProcessWordsReader(strings.NewReader(book)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func assertWords(t *testing.T, got, want []Word) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d words, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("word %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWordAccessors(t *testing.T) {
+	words := ProcessWords([]string{"call", "me", "ishmael"})
+	want := []string{"CALL", "ME", "ISHMAEL"}
+	for i, w := range words {
+		if w.Word() != want[i] || w.Index() != i {
+			t.Errorf("got %q@%d, want %q@%d", w.Word(), w.Index(), want[i], i)
+		}
+	}
+}
+
+func TestWordEqual(t *testing.T) {
+	w := Word{"CALL", 0}
+	if !w.Equal(Word{"CALL", 0}) {
+		t.Error("equal words compare unequal")
+	}
+	if w.Equal(Word{"CALL", 1}) || w.Equal(Word{"ME", 0}) {
+		t.Error("different words compare equal")
+	}
+
+	raw := []string{"call", "me"}
+	if !slices.EqualFunc(ProcessWords(raw), ProcessWordsFaster(raw), Word.Equal) {
+		t.Error("slices.EqualFunc reports identical results as different")
+	}
+}
+
+func TestCompareWords(t *testing.T) {
+	tests := []struct {
+		a, b Word
+		want int
+	}{
+		{Word{"AHAB", 5}, Word{"WHALE", 1}, -1},
+		{Word{"WHALE", 1}, Word{"AHAB", 5}, 1},
+		{Word{"THE", 0}, Word{"THE", 3}, -1},
+		{Word{"THE", 3}, Word{"THE", 0}, 1},
+		{Word{"THE", 3}, Word{"THE", 3}, 0},
+	}
+	for _, tt := range tests {
+		if got := CompareWords(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareWords(%v, %v): got %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	words := []Word{{"WHALE", 4}, {"THE", 3}, {"AHAB", 2}, {"THE", 0}}
+	slices.SortFunc(words, CompareWords)
+	assertWords(t, words, []Word{{"AHAB", 2}, {"THE", 0}, {"THE", 3}, {"WHALE", 4}})
+}
+
+func TestWordString(t *testing.T) {
+	if got, want := (Word{"HELLO", 3}).String(), "HELLO@3"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprint(Word{"WHALE", -1}), "WHALE@-1"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	w := Word{"ISHMAEL", 194470}
+	if allocs := testing.AllocsPerRun(100, func() { textSink = w.String() }); allocs != 1 {
+		t.Errorf("got %v allocations, want 1", allocs)
+	}
+}
+
+func TestFormatWords(t *testing.T) {
+	if got, want := FormatWords(ProcessWords([]string{"call", "me", "ishmael"})), "[CALL@0 ME@1 ISHMAEL@2]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := FormatWords(nil), "[]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWordJSON(t *testing.T) {
+	data, err := json.Marshal(Word{"CALL", 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"word":"CALL","index":3}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	words := ProcessWords([]string{"call", "me", "\"ishmael\""})
+	data, err = json.Marshal(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Word
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, got, words)
+}
+
+func TestProcessWordsWith(t *testing.T) {
+	raw := []string{" Call", "ME ", " Ishmael "}
+	tests := []struct {
+		name      string
+		transform func(string) string
+		want      []string
+	}{
+		{"lower", strings.ToLower, []string{" call", "me ", " ishmael "}},
+		{"trim", strings.TrimSpace, []string{"Call", "ME", "Ishmael"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words := ProcessWordsWith(raw, tt.transform)
+			if len(words) != len(tt.want) {
+				t.Fatalf("got %d words, want %d", len(words), len(tt.want))
+			}
+			for i, w := range words {
+				if w.Word() != tt.want[i] || w.Index() != i {
+					t.Errorf("got %q@%d, want %q@%d", w.Word(), w.Index(), tt.want[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessWordsParallel(t *testing.T) {
+	raw := CorpusWords()[:1001]
+	want := ProcessWordsFaster(raw)
+	for _, workers := range []int{-1, 0, 1, 3, 7, len(raw), len(raw) + 5} {
+		got := ProcessWordsParallel(raw, workers)
+		if len(got) != len(want) {
+			t.Fatalf("workers=%d: got %d words, want %d", workers, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("workers=%d: got %v at %d, want %v", workers, got[i], i, want[i])
+			}
+		}
+	}
+
+	if got := ProcessWordsParallel(nil, 4); len(got) != 0 {
+		t.Errorf("got %d words for nil input, want 0", len(got))
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestProcessWordsReader(t *testing.T) {
+	words, err := ProcessWordsReader(strings.NewReader("  call me\n\tishmael "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"CALL", "ME", "ISHMAEL"}
+	if len(words) != len(want) {
+		t.Fatalf("got %d words, want %d", len(words), len(want))
+	}
+	for i, w := range words {
+		if w.Word() != want[i] || w.Index() != i {
+			t.Errorf("got %q@%d, want %q@%d", w.Word(), w.Index(), want[i], i)
+		}
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := ProcessWordsReader(errReader{readErr}); !errors.Is(err, readErr) {
+		t.Errorf("got error %v, want %v", err, readErr)
+	}
+}
+
+func TestProcessWordsFunc(t *testing.T) {
+	raw := []string{"call", "me", "ishmael"}
+	var got []Word
+	ProcessWordsFunc(raw, func(w Word) { got = append(got, w) })
+	assertWords(t, got, ProcessWordsFaster(raw))
+}
+
+func TestProcessWordsNonEmpty(t *testing.T) {
+	raw := strings.Split(" call  me\t \nishmael ", " ")
+	assertWords(t, ProcessWordsNonEmpty(raw), []Word{{"CALL", 1}, {"ME\t", 3}, {"\nISHMAEL", 4}})
+}
+
+func TestProcessWordsInto(t *testing.T) {
+	buf := make([]Word, 0, 4)
+	buf = ProcessWordsInto(buf, []string{"call", "me"})
+	assertWords(t, buf, []Word{{"CALL", 0}, {"ME", 1}})
+
+	reused := ProcessWordsInto(buf, []string{"ishmael"})
+	assertWords(t, reused, []Word{{"ISHMAEL", 0}})
+	if &reused[0] != &buf[0] {
+		t.Error("buffer with enough capacity was not reused")
+	}
+
+	grown := ProcessWordsInto(nil, []string{"call", "me", "ishmael"})
+	assertWords(t, grown, ProcessWordsFaster([]string{"call", "me", "ishmael"}))
+}
+
+var errInvalidUTF8 = errors.New("invalid UTF-8")
+
+func upperValidUTF8(word string) (string, error) {
+	if !utf8.ValidString(word) {
+		return "", errInvalidUTF8
+	}
+	return strings.ToUpper(word), nil
+}
+
+func TestProcessWordsValidated(t *testing.T) {
+	raw := []string{"call", "me", "ishmael"}
+	words, err := ProcessWordsValidated(raw, upperValidUTF8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, words, ProcessWordsWith(raw, strings.ToUpper))
+
+	words, err = ProcessWordsValidated([]string{"call", "me", "\xffishmael", "again"}, upperValidUTF8)
+	if !errors.Is(err, errInvalidUTF8) {
+		t.Fatalf("got error %v, want %v", err, errInvalidUTF8)
+	}
+	if want := "word 2: invalid UTF-8"; err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+	assertWords(t, words, []Word{{"CALL", 0}, {"ME", 1}})
+}
+
+func TestProcessWordsPairs(t *testing.T) {
+	pairs := ProcessWordsPairs([]string{"call", "Me"})
+	want := []WordPair{{"call", "CALL", 0}, {"Me", "ME", 1}}
+	if len(pairs) != len(want) {
+		t.Fatalf("got %d pairs, want %d", len(pairs), len(want))
+	}
+	for i := range want {
+		if pairs[i] != want[i] {
+			t.Errorf("pair %d: got %v, want %v", i, pairs[i], want[i])
+		}
+	}
+}
+
+func TestProcessText(t *testing.T) {
+	words := ProcessText("  Call me\tIshmael.\n\nSome  years ago\u00a0- never ")
+	assertWords(t, words, []Word{
+		{"CALL", 0}, {"ME", 1}, {"ISHMAEL.", 2}, {"SOME", 3}, {"YEARS", 4}, {"AGO", 5}, {"-", 6}, {"NEVER", 7},
+	})
+}
+
+func TestProcessWordsStripPunct(t *testing.T) {
+	raw := []string{"Hello,", "Hello", `"world."`, "don't", "...", "(whale)"}
+	assertWords(t, ProcessWordsStripPunct(raw), []Word{
+		{"HELLO", 0}, {"HELLO", 1}, {"WORLD", 2}, {"DON'T", 3}, {"", 4}, {"WHALE", 5},
+	})
+}
+
+var ( // keep results on the heap so allocation counts match real callers
+	stringSink []string
+	wordSink   []Word
+	textSink   string
+)
+
+func TestProcessSlice(t *testing.T) {
+	in := []int{1, 22, 333}
+	got := ProcessSlice(in, strconv.Itoa)
+	want := []string{"1", "22", "333"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q at %d, want %q", got[i], i, want[i])
+		}
+	}
+
+	small := []int{1, 2, 3, 4, 5} // strconv.Itoa doesn't allocate for small numbers
+	if allocs := testing.AllocsPerRun(100, func() { stringSink = ProcessSlice(small, strconv.Itoa) }); allocs != 1 {
+		t.Errorf("got %v allocations, want 1", allocs)
+	}
+	upper := []string{"CALL", "ME", "ISHMAEL"} // strings.ToUpper doesn't allocate for upper case input
+	if allocs := testing.AllocsPerRun(100, func() { wordSink = ProcessWordsFaster(upper) }); allocs != 1 {
+		t.Errorf("got %v allocations for ProcessWordsFaster, want 1", allocs)
+	}
+}
+
+func TestProcessWordsIndexed(t *testing.T) {
+	raw := CorpusWords()[:100]
+	assertWords(t, ProcessWordsIndexed(raw), ProcessWordsFaster(raw))
+}
+
+func TestProcessWordsContext(t *testing.T) {
+	raw := CorpusWords()[:3000]
+	words, err := ProcessWordsContext(context.Background(), raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, words, ProcessWordsFaster(raw))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	words, err = ProcessWordsContext(ctx, raw)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if len(words) != 0 {
+		t.Errorf("got %d words from a cancelled context, want 0", len(words))
+	}
+
+	words, err = ProcessWordsContext(&countdownContext{context.Background(), 2}, raw)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	assertWords(t, words, ProcessWordsFaster(raw[:2*ctxCheckInterval]))
+}
+
+// countdownContext reports itself cancelled after Err has been called checks times.
+type countdownContext struct {
+	context.Context
+	checks int
+}
+
+func (c *countdownContext) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestProcessWordsFold(t *testing.T) {
+	raw := []string{"straße", "istanbul", "Ishmael"}
+	tests := []struct {
+		tag  language.Tag
+		want []Word
+	}{
+		{language.Und, ProcessWordsFaster(raw)},
+		{language.German, []Word{{"STRASSE", 0}, {"ISTANBUL", 1}, {"ISHMAEL", 2}}},
+		{language.Turkish, []Word{{"STRASSE", 0}, {"İSTANBUL", 1}, {"ISHMAEL", 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag.String(), func(t *testing.T) {
+			assertWords(t, ProcessWordsFold(raw, tt.tag), tt.want)
+		})
+	}
+}
+
+func TestProcessWordsSorted(t *testing.T) {
+	raw := []string{"the", "Whale", "ahab", "The", "whale", "THE"}
+	assertWords(t, ProcessWordsSorted(raw), []Word{
+		{"AHAB", 2}, {"THE", 0}, {"THE", 3}, {"THE", 5}, {"WHALE", 1}, {"WHALE", 4},
+	})
+}
+
+func TestProcessWordsBatched(t *testing.T) {
+	raw := []string{"call", "me", "ishmael", "some", "years"}
+	batches := ProcessWordsBatched(raw, 2)
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(batches))
+	}
+	assertWords(t, batches[0], []Word{{"CALL", 0}, {"ME", 1}})
+	assertWords(t, batches[1], []Word{{"ISHMAEL", 2}, {"SOME", 3}})
+	assertWords(t, batches[2], []Word{{"YEARS", 4}})
+
+	_ = append(batches[0], Word{"EXTRA", 9})
+	assertWords(t, batches[1], []Word{{"ISHMAEL", 2}, {"SOME", 3}})
+
+	for _, size := range []int{0, -1} {
+		batches := ProcessWordsBatched(raw, size)
+		if len(batches) != 1 {
+			t.Fatalf("batchSize=%d: got %d batches, want 1", size, len(batches))
+		}
+		assertWords(t, batches[0], ProcessWordsFaster(raw))
+	}
+
+	for _, size := range []int{len(raw), math.MaxInt - 1, math.MaxInt} {
+		batches := ProcessWordsBatched(raw, size)
+		if len(batches) != 1 {
+			t.Fatalf("batchSize=%d: got %d batches, want 1", size, len(batches))
+		}
+		assertWords(t, batches[0], ProcessWordsFaster(raw))
+	}
+
+	if batches := ProcessWordsBatched(nil, 2); len(batches) != 0 {
+		t.Errorf("got %d batches for nil input, want 0", len(batches))
+	}
+}
+
+func TestProcessWordsFrom(t *testing.T) {
+	raw := CorpusWords()[:100]
+	assertWords(t, ProcessWordsFrom(raw, 0), ProcessWords(raw))
+
+	var accumulated []Word
+	for start := 0; start < len(raw); start += 30 {
+		end := start + 30
+		if end > len(raw) {
+			end = len(raw)
+		}
+		accumulated = append(accumulated, ProcessWordsFrom(raw[start:end], len(accumulated))...)
+	}
+	assertWords(t, accumulated, ProcessWords(raw))
+}
+
+func TestProcessWordsInterned(t *testing.T) {
+	raw := []string{"the", "whale", "The", "THE"}
+	words := ProcessWordsInterned(raw)
+	assertWords(t, words, ProcessWordsFaster(raw))
+	if unsafe.StringData(words[0].word) != unsafe.StringData(words[2].word) {
+		t.Error("repeated words don't share a string")
+	}
+}
+
+func TestProcessWordsTruncate(t *testing.T) {
+	raw := []string{"ishmael", "me", "straße", "日本語です", ""}
+	assertWords(t, ProcessWordsTruncate(raw, 4), []Word{
+		{"ISHM", 0}, {"ME", 1}, {"STRA", 2}, {"日本語で", 3}, {"", 4},
+	})
+	assertWords(t, ProcessWordsTruncate(raw, 5), []Word{
+		{"ISHMA", 0}, {"ME", 1}, {"STRAß", 2}, {"日本語です", 3}, {"", 4},
+	})
+	for _, n := range []int{0, -1} {
+		assertWords(t, ProcessWordsTruncate(raw, n), ProcessWordsFaster(raw))
+	}
+}
+
+func TestFirstN(t *testing.T) {
+	words := ProcessWordsFaster(CorpusWords())
+	size := int(unsafe.Sizeof(Word{}))
+
+	resliced := FirstN(words, 10)
+	assertWords(t, resliced, words[:10])
+	if cap(resliced) != cap(words) {
+		t.Errorf("got cap %d, want the full backing array's %d", cap(resliced), cap(words))
+	}
+	t.Logf("FirstN(words, 10) keeps %d bytes of Words alive", cap(resliced)*size)
+
+	copied := FirstNCopy(words, 10)
+	assertWords(t, copied, words[:10])
+	if cap(copied) != 10 {
+		t.Errorf("got cap %d, want 10", cap(copied))
+	}
+	t.Logf("FirstNCopy(words, 10) keeps %d bytes of Words alive", cap(copied)*size)
+
+	copied[0] = Word{"CHANGED", 0}
+	if words[0] == copied[0] {
+		t.Error("FirstNCopy shares memory with its input")
+	}
+
+	for _, n := range []int{-1, 0, len(words) + 1} {
+		if got, want := len(FirstN(words, n)), clamp(n, len(words)); got != want {
+			t.Errorf("FirstN(words, %d): got %d words, want %d", n, got, want)
+		}
+		if got, want := len(FirstNCopy(words, n)), clamp(n, len(words)); got != want {
+			t.Errorf("FirstNCopy(words, %d): got %d words, want %d", n, got, want)
+		}
+	}
+}
+
+func TestProcessWordsBytes(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		" ",
+		"call me  ishmael ",
+		"straße café naïve mixed ÀB",
+		"invalid \xff utf8",
+		book,
+	} {
+		in := []byte(raw)
+		want := ProcessWordsFaster(strings.Split(raw, " "))
+		if raw == "" {
+			want = []Word{} // documented exception: no words instead of Split's single empty one
+		}
+		assertWords(t, ProcessWordsBytes(in), want)
+		if string(in) != raw {
+			t.Errorf("input was modified: got %q, want %q", in, raw)
+		}
+	}
+}
+
+func TestProcessNGrams(t *testing.T) {
+	raw := []string{"call", "me", "ishmael", "now"}
+	assertWords(t, ProcessNGrams(raw, 1), ProcessWordsFaster(raw))
+	assertWords(t, ProcessNGrams(raw, 2), []Word{{"CALL ME", 0}, {"ME ISHMAEL", 1}, {"ISHMAEL NOW", 2}})
+	assertWords(t, ProcessNGrams(raw, 3), []Word{{"CALL ME ISHMAEL", 0}, {"ME ISHMAEL NOW", 1}})
+	assertWords(t, ProcessNGrams(raw, 4), []Word{{"CALL ME ISHMAEL NOW", 0}})
+	for _, n := range []int{5, 0, -1} {
+		if got := ProcessNGrams(raw, n); got == nil || len(got) != 0 {
+			t.Errorf("n=%d: got %v, want an empty slice", n, got)
+		}
+	}
+}
+
+func TestProcessWordsDelim(t *testing.T) {
+	tests := []struct {
+		raw, delim string
+		want       []Word
+	}{
+		{"info|disk full|retry", "|", []Word{{"INFO", 0}, {"DISK FULL", 1}, {"RETRY", 2}}},
+		{"a\tb\t\tc", "\t", []Word{{"A", 0}, {"B", 1}, {"", 2}, {"C", 3}}},
+		{"call::me", "::", []Word{{"CALL", 0}, {"ME", 1}}},
+		{" call  me\nishmael ", "", []Word{{"CALL", 0}, {"ME", 1}, {"ISHMAEL", 2}}},
+		{"", ",", []Word{}},
+		{",", ",", []Word{{"", 0}, {"", 1}}},
+		{"", "", []Word{}},
+	}
+	for _, tt := range tests {
+		assertWords(t, ProcessWordsDelim(tt.raw, tt.delim), tt.want)
+	}
+}
+
+func TestProcessWordsArena(t *testing.T) {
+	arena := make([]Word, 5)
+	first, rest := ProcessWordsArena([]string{"call", "me"}, arena)
+	assertWords(t, first, []Word{{"CALL", 0}, {"ME", 1}})
+	if len(rest) != 3 || &arena[2] != &rest[0] {
+		t.Fatalf("got %d words of arena left, want the last 3", len(rest))
+	}
+
+	second, rest := ProcessWordsArena([]string{"ishmael"}, rest)
+	assertWords(t, second, []Word{{"ISHMAEL", 0}})
+	assertWords(t, arena[:3], []Word{{"CALL", 0}, {"ME", 1}, {"ISHMAEL", 0}})
+
+	_ = append(first, Word{"EXTRA", 9})
+	assertWords(t, second, []Word{{"ISHMAEL", 0}})
+
+	big, left := ProcessWordsArena([]string{"a", "b", "c"}, rest)
+	assertWords(t, big, []Word{{"A", 0}, {"B", 1}, {"C", 2}})
+	if len(left) != 2 || &left[0] != &rest[0] {
+		t.Error("arena too small for the input was not returned untouched")
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	funcs := []struct {
+		name string
+		fn   func([]string) []Word
+	}{
+		{"ProcessWords", ProcessWords},
+		{"ProcessWordsFaster", ProcessWordsFaster},
+		{"ProcessWordsIndexed", ProcessWordsIndexed},
+		{"ProcessWordsDoubling", ProcessWordsDoubling},
+		{"ProcessWordsUntil", func(raw []string) []Word { return ProcessWordsUntil(raw, func(Word) bool { return false }) }},
+		{"ProcessWordsWith", func(raw []string) []Word { return ProcessWordsWith(raw, strings.ToLower) }},
+		{"ProcessWordsMemo", func(raw []string) []Word { return ProcessWordsMemo(raw, strings.ToLower) }},
+		{"ProcessWordsFrom", func(raw []string) []Word { return ProcessWordsFrom(raw, 10) }},
+		{"ProcessWordsRange", func(raw []string) []Word { return ProcessWordsRange(raw, 0, len(raw)) }},
+		{"ProcessWordsParallel", func(raw []string) []Word { return ProcessWordsParallel(raw, 0) }},
+		{"ProcessWordsInto", func(raw []string) []Word { return ProcessWordsInto(nil, raw) }},
+		{"ProcessWordsArena", func(raw []string) []Word { words, _ := ProcessWordsArena(raw, nil); return words }},
+		{"ProcessWordsPooled", func(raw []string) []Word { return *ProcessWordsPooled(raw) }},
+		{"ProcessWordsContext", func(raw []string) []Word { words, _ := ProcessWordsContext(context.Background(), raw); return words }},
+		{"ProcessWordsValidated", func(raw []string) []Word { words, _ := ProcessWordsValidated(raw, upperValidUTF8); return words }},
+		{"ProcessWordsStripPunct", ProcessWordsStripPunct},
+		{"ProcessWordsPrefixed", func(raw []string) []Word { return ProcessWordsPrefixed(raw, "word:") }},
+		{"ProcessWordsFold", func(raw []string) []Word { return ProcessWordsFold(raw, language.German) }},
+		{"ProcessWordsTitle", ProcessWordsTitle},
+		{"ProcessWordsSorted", ProcessWordsSorted},
+		{"ProcessWordsInterned", ProcessWordsInterned},
+		{"ProcessWordsTruncate", func(raw []string) []Word { return ProcessWordsTruncate(raw, 3) }},
+		{"ProcessNGrams", func(raw []string) []Word { return ProcessNGrams(raw, 1) }},
+		{"ProcessWithStats", func(raw []string) []Word { words, _ := ProcessWithStats(raw); return words }},
+		{"ProcessWordsUnique", func(raw []string) []Word { unique, _ := ProcessWordsUnique(raw); return unique }},
+		{"WordProcessor", WordProcessor{}.Process},
+		{"ProcessWordsNonEmpty", ProcessWordsNonEmpty},
+		{"WordProcessor.SkipEmpty", WordProcessor{SkipEmpty: true}.Process},
+	}
+	inputs := []struct {
+		name string
+		raw  []string
+	}{
+		{"nil", nil},
+		{"empty", []string{}},
+		{"empty string", []string{""}},
+		{"space", []string{" "}},
+	}
+	for _, f := range funcs {
+		for _, in := range inputs {
+			t.Run(f.name+"/"+in.name, func(t *testing.T) {
+				got := f.fn(in.raw)
+				if got == nil {
+					t.Fatal("got nil, want a non-nil slice")
+				}
+				want := len(in.raw)
+				if strings.HasSuffix(f.name, "NonEmpty") || strings.HasSuffix(f.name, "SkipEmpty") {
+					want = 0
+				}
+				if len(got) != want {
+					t.Errorf("got %d words, want %d", len(got), want)
+				}
+			})
+		}
+	}
+
+	fromReader, _ := ProcessWordsReader(strings.NewReader(""))
+	for name, got := range map[string][]Word{
+		"ProcessWordsReader": fromReader,
+		"ProcessText":        ProcessText(""),
+		"ProcessText space":  ProcessText(" "),
+		"ProcessWordsDelim":  ProcessWordsDelim("", "|"),
+		"ProcessWordsBytes":  ProcessWordsBytes(nil),
+	} {
+		if got == nil || len(got) != 0 {
+			t.Errorf("%s: got %v, want a non-nil empty slice", name, got)
+		}
+	}
+	if batches := ProcessWordsBatched(nil, 0); len(batches) != 1 || batches[0] == nil || len(batches[0]) != 0 {
+		t.Errorf("ProcessWordsBatched(nil, 0): got %v, want one empty batch", batches)
+	}
+	if batches := ProcessWordsBatched(nil, 2); batches == nil || len(batches) != 0 {
+		t.Errorf("ProcessWordsBatched(nil, 2): got %v, want no batches", batches)
+	}
+	if data, _ := json.Marshal(ProcessWords(nil)); string(data) != "[]" {
+		t.Errorf("got %s for empty JSON, want []", data)
+	}
+}
+
+func FuzzProcessText(f *testing.F) {
+	for _, seed := range []string{
+		// Only the opening of the book: with the full 1.2MB text as a seed the
+		// fuzzer's coverage pass over each input is so slow that a 10s run
+		// executes a handful of inputs instead of tens of thousands.
+		book[:1<<12],
+		"",
+		strings.Repeat(" ", 1<<12),
+		"\xff\xfe\xfd invalid \xc3",
+		"\x00\x01\x1f\x7f control\tchars\r\n",
+		"  　unicode spaces\u0085",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		words := ProcessText(raw)
+		for i, w := range words {
+			if w.index != i {
+				t.Fatalf("word %d has index %d, want indices 0..%d in order", i, w.index, len(words)-1)
+			}
+			if w.word == "" {
+				t.Fatalf("word %d is empty", i)
+			}
+			if strings.IndexFunc(w.word, unicode.IsSpace) >= 0 {
+				t.Fatalf("word %d (%q) contains whitespace", i, w.word)
+			}
+		}
+		assertWords(t, ProcessText(raw), words)
+	})
+}
+
+func TestProcessWordsTitle(t *testing.T) {
+	raw := []string{"hello", "HELLO", "(hello)", `"call`, "ishmael.", "don't", ""}
+	assertWords(t, ProcessWordsTitle(raw), []Word{
+		{"Hello", 0}, {"Hello", 1}, {"(Hello)", 2}, {`"Call`, 3}, {"Ishmael.", 4}, {"Don't", 5}, {"", 6},
+	})
+}
+
+func TestProcessWordsDoubling(t *testing.T) {
+	raw := CorpusWords()
+	words := ProcessWordsDoubling(raw)
+	assertWords(t, words, ProcessWordsFaster(raw))
+	if cap(words) != 1<<18-1 {
+		t.Errorf("got cap %d, want %d", cap(words), 1<<18-1)
+	}
+}
+
+func TestProcessWordsChan(t *testing.T) {
+	raw := CorpusWords()[:1000]
+	var got []Word
+	for w := range ProcessWordsChan(context.Background(), raw) {
+		got = append(got, w)
+	}
+	assertWords(t, got, ProcessWordsFaster(raw))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := ProcessWordsChan(ctx, raw)
+	first := <-ch
+	cancel()
+	n := 1
+	for range ch {
+		n++
+	}
+	if first != (Word{process(raw[0]), 0}) {
+		t.Errorf("got first word %v, want %v", first, Word{process(raw[0]), 0})
+	}
+	if limit := 1 + wordChanBuffer + 1; n > limit { // first, whatever was buffered, and one in-flight send
+		t.Errorf("got %d words after cancelling, want at most %d", n, limit)
+	}
+}
+
+func TestProcessWordsPrefixed(t *testing.T) {
+	raw := append(CorpusWords()[:1000], "straße", "日本", "\xffbad", "")
+	for _, prefix := range []string{"", "word:"} {
+		want := ProcessWordsWith(raw, func(w string) string { return prefix + process(w) })
+		assertWords(t, ProcessWordsPrefixed(raw, prefix), want)
+	}
+
+	upper := []string{"CALL", "ME", "ISHMAEL"} // process doesn't allocate for upper case input
+	if allocs := testing.AllocsPerRun(100, func() { wordSink = ProcessWordsPrefixed(upper, "word:") }); allocs > 3 {
+		t.Errorf("got %v allocations, want at most 3: the builder, the offsets and the result", allocs)
+	}
+}
+
+func TestProcessWordsRange(t *testing.T) {
+	raw := []string{"call", "me", "ishmael", "some", "years"}
+	tests := []struct {
+		start, end int
+		want       []Word
+	}{
+		{1, 3, []Word{{"ME", 1}, {"ISHMAEL", 2}}},
+		{0, 5, ProcessWordsFaster(raw)},
+		{-2, 1, []Word{{"CALL", 0}}},
+		{3, 100, []Word{{"SOME", 3}, {"YEARS", 4}}},
+		{3, 3, []Word{}},
+		{4, 2, []Word{}},
+		{7, 9, []Word{}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d:%d", tt.start, tt.end), func(t *testing.T) {
+			assertWords(t, ProcessWordsRange(raw, tt.start, tt.end), tt.want)
+		})
+	}
+}
+
+func TestProcessWordsMap(t *testing.T) {
+	raw := CorpusWords()[:1000]
+	m := ProcessWordsMap(raw)
+	if len(m) != len(raw) {
+		t.Fatalf("got %d words, want %d", len(m), len(raw))
+	}
+	for _, w := range ProcessWordsFaster(raw) {
+		if m[w.index] != w {
+			t.Errorf("index %d: got %v, want %v", w.index, m[w.index], w)
+		}
+	}
+}
+
+func TestCloneWords(t *testing.T) {
+	words := ProcessWordsFaster([]string{"call", "me", "ishmael"})
+	first := FirstN(words, 2)
+	clone := CloneWords(first)
+	assertWords(t, clone, first)
+
+	clone[0] = Word{"CHANGED", 0}
+	_ = append(clone, Word{"EXTRA", 9})
+	assertWords(t, words, []Word{{"CALL", 0}, {"ME", 1}, {"ISHMAEL", 2}})
+
+	first[0] = Word{"CHANGED", 0}
+	if words[0] != first[0] {
+		t.Error("FirstN no longer shares its backing array, update the CloneWords docs")
+	}
+}
+
+func TestProcessWordsUntil(t *testing.T) {
+	raw := []string{"call", "me", "ishmael", "some", "years"}
+	isIshmael := func(w Word) bool { return w.word == "ISHMAEL" }
+	assertWords(t, ProcessWordsUntil(raw, isIshmael), []Word{{"CALL", 0}, {"ME", 1}, {"ISHMAEL", 2}})
+
+	never := func(Word) bool { return false }
+	assertWords(t, ProcessWordsUntil(raw, never), ProcessWordsFaster(raw))
+
+	calls := 0
+	ProcessWordsUntil(raw, func(Word) bool { calls++; return true })
+	if calls != 1 {
+		t.Errorf("got %d calls to stop, want 1", calls)
+	}
+}
+
+func TestProcessWordsMemo(t *testing.T) {
+	raw := CorpusWords()[:5000]
+	calls := 0
+	words := ProcessWordsMemo(raw, func(w string) string {
+		calls++
+		return slowProcess(w)
+	})
+	assertWords(t, words, ProcessWordsWith(raw, slowProcess))
+
+	distinct := make(map[string]struct{})
+	for _, w := range raw {
+		distinct[w] = struct{}{}
+	}
+	if calls != len(distinct) {
+		t.Errorf("got %d transform calls, want one per distinct word (%d)", calls, len(distinct))
 	}
 }